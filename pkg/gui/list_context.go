@@ -2,8 +2,10 @@ package gui
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/jesseduffield/gocui"
+	"github.com/jesseduffield/lazygit/pkg/gui/style"
 )
 
 type ListContext struct {
@@ -16,16 +18,97 @@ type ListContext struct {
 	// the boolean here tells us whether the item is nil. This is needed because you can't work it out on the calling end once the pointer is wrapped in an interface (unless you want to use reflection)
 	SelectedItem    func() (ListItem, bool)
 	OnGetPanelState func() IListPanelState
-	// if this is true, we'll call GetDisplayStrings for just the visible part of the
-	// view and re-render that. This is useful when you need to render different
-	// content based on the selection (e.g. for showing the selected commit)
+	// Deprecated: windowed rendering (only fetching display strings for the
+	// visible part of the view) is now the default behaviour for every list
+	// context, so this flag no longer changes anything. Kept around so
+	// existing call sites that set it don't need to be touched.
 	RenderSelection bool
+	// ContentVersion, if set, should return a number that changes whenever the
+	// underlying data behind GetDisplayStrings changes (e.g. a new `git log`
+	// came in). We use it, together with the bounds of the last window we
+	// rendered, to avoid recomputing display strings when nothing relevant
+	// has changed (e.g. the cursor moved within an already-rendered window).
+	ContentVersion func() int
+
+	// GetItemAtIndex, if set, resolves an arbitrary index (not just the
+	// cursor's) back to a ListItem. It's required for GetSelectedItems to
+	// return more than the cursor item; panels that don't support
+	// multi-selection can leave it nil.
+	GetItemAtIndex func(idx int) (ListItem, bool)
+
+	// OnSelectionChanged, if set, is called whenever the multi-selection
+	// (toggled items or an extended range) changes as a result of
+	// handleToggleSelect, handleExtendSelectionUp/Down, handleSelectAll or
+	// handleClearSelection. Plain single-item navigation (arrow keys,
+	// paging, a plain click, a search jump) calls it only when it drops an
+	// existing range or selection; otherwise it just moves the anchor.
+	OnSelectionChanged func()
 
 	Gui *Gui
 
+	// lastWindow remembers the (startIdx, length, contentVersion) we last
+	// asked GetDisplayStrings for, so that re-rendering the same window is a
+	// no-op.
+	lastWindow *renderedWindow
+
+	// selection holds any multi/range-selection on top of the single
+	// cursor position tracked by IListPanelState. A nil selection means
+	// "just the cursor item is selected", which is the common case and
+	// keeps existing single-item callers working unchanged.
+	selection *selectionSet
+
+	// selectionVersion is bumped every time the selection changes. It's
+	// folded into the rendered-window cache key so that toggling/extending
+	// the selection forces a re-render even when the viewport itself
+	// hasn't scrolled (otherwise the cache's early-return would leave the
+	// new highlight unrendered).
+	selectionVersion int
+
 	*BasicContext
 }
 
+// selectionSet tracks which rows are selected beyond the cursor: an
+// anchor/rangeEnd pair for shift-extended ranges, plus any individually
+// toggled indices for ctrl-click/ctrl-toggle.
+type selectionSet struct {
+	active      bool
+	anchorIdx   int
+	rangeEndIdx int
+	toggled     map[int]bool
+}
+
+func (s *selectionSet) contains(idx int) bool {
+	if s == nil {
+		return false
+	}
+	if s.active {
+		lo, hi := s.anchorIdx, s.rangeEndIdx
+		if lo > hi {
+			lo, hi = hi, lo
+		}
+		if idx >= lo && idx <= hi {
+			return true
+		}
+	}
+	return s.toggled[idx]
+}
+
+// multiSelectHighlightStyle marks rows that are part of a multi-selection
+// but aren't the cursor row, which gocui already highlights on its own.
+var multiSelectHighlightStyle = style.New().SetBold()
+
+// renderWindowOverscan is the number of extra rows we request above and
+// below the visible viewport, so that a small scroll or selection change
+// near the edge of the window doesn't immediately require another fetch.
+const renderWindowOverscan = 10
+
+type renderedWindow struct {
+	startIdx         int
+	length           int
+	contentVersion   int
+	selectionVersion int
+}
+
 type IListContext interface {
 	GetSelectedItem() (ListItem, bool)
 	GetSelectedItemId() string
@@ -40,10 +123,18 @@ type IListContext interface {
 	handleGotoBottom() error
 	handlePrevPage() error
 	handleClick() error
+	handleShiftClick() error
+	handleCtrlClick() error
+	handleToggleSelect() error
+	handleExtendSelectionUp() error
+	handleExtendSelectionDown() error
+	handleSelectAll() error
+	handleClearSelection() error
 	onSearchSelect(selectedLineIdx int) error
 	FocusLine()
 
 	GetPanelState() IListPanelState
+	GetSelectedItems() []ListItem
 
 	Context
 }
@@ -74,14 +165,84 @@ func (self *ListContext) FocusLine() {
 
 	// we need a way of knowing whether we've rendered to the view yet.
 	view.FocusPoint(view.OriginX(), self.GetPanelState().GetSelectedLineIdx())
-	if self.RenderSelection {
-		_, originY := view.Origin()
-		displayStrings := self.GetDisplayStrings(originY, view.InnerHeight())
-		self.Gui.renderDisplayStringsAtPos(view, originY, displayStrings)
+	if self.GetDisplayStrings != nil {
+		self.renderVisibleWindow(view)
 	}
 	view.Footer = formatListFooter(self.GetPanelState().GetSelectedLineIdx(), self.GetItemsLength())
 }
 
+// renderVisibleWindow fetches display strings for the visible portion of the
+// view (plus a small overscan buffer) and pushes them at the right offset,
+// instead of materializing every item in the list. This keeps scrolling
+// through huge panels (e.g. a commits view after `git log` on a big repo)
+// O(viewport) rather than O(N) per key press.
+func (self *ListContext) renderVisibleWindow(view *gocui.View) {
+	itemsLength := self.GetItemsLength()
+
+	_, originY := view.Origin()
+	startIdx, length := computeRenderWindow(itemsLength, originY, view.InnerHeight())
+
+	contentVersion := 0
+	if self.ContentVersion != nil {
+		contentVersion = self.ContentVersion()
+	}
+
+	window := renderedWindow{
+		startIdx:         startIdx,
+		length:           length,
+		contentVersion:   contentVersion,
+		selectionVersion: self.selectionVersion,
+	}
+	if self.lastWindow != nil && *self.lastWindow == window {
+		return
+	}
+	self.lastWindow = &window
+
+	displayStrings := self.GetDisplayStrings(startIdx, length)
+	self.applySelectionHighlight(displayStrings, startIdx)
+	self.Gui.renderDisplayStringsAtPos(view, startIdx, displayStrings)
+}
+
+// computeRenderWindow works out which slice of the underlying items to fetch
+// display strings for: the visible viewport (originY to originY+innerHeight)
+// plus renderWindowOverscan on either side, clamped to the actual item
+// count. Pulled out of renderVisibleWindow so the bounds math can be tested
+// without a real gocui.View.
+func computeRenderWindow(itemsLength int, originY int, innerHeight int) (startIdx int, length int) {
+	startIdx = originY - renderWindowOverscan
+	if startIdx < 0 {
+		startIdx = 0
+	}
+	length = innerHeight + (originY - startIdx) + renderWindowOverscan + 1
+	if startIdx+length > itemsLength {
+		length = itemsLength - startIdx
+	}
+	if length < 0 {
+		length = 0
+	}
+	return startIdx, length
+}
+
+// applySelectionHighlight re-styles every row that's part of a
+// multi-selection but isn't the cursor row itself, since gocui already
+// highlights the cursor row for us.
+func (self *ListContext) applySelectionHighlight(displayStrings [][]string, startIdx int) {
+	if self.selection == nil {
+		return
+	}
+
+	cursorIdx := self.GetPanelState().GetSelectedLineIdx()
+	for i, row := range displayStrings {
+		idx := startIdx + i
+		if idx == cursorIdx || !self.selection.contains(idx) {
+			continue
+		}
+		for col := range row {
+			row[col] = multiSelectHighlightStyle.Sprint(row[col])
+		}
+	}
+}
+
 func formatListFooter(selectedLineIdx int, length int) string {
 	return fmt.Sprintf("%d of %d", selectedLineIdx+1, length)
 }
@@ -100,6 +261,51 @@ func (self *ListContext) GetSelectedItemId() string {
 	return item.ID()
 }
 
+// GetSelectedItems returns every selected ListItem: just the cursor item if
+// there's no active multi-selection, or the union of the cursor item, the
+// toggled indices and the extended range otherwise, in index order. Batch operations (cherry-picking a
+// range of commits, staging multiple files, dropping several stashes) read
+// from this instead of GetSelectedItem.
+func (self *ListContext) GetSelectedItems() []ListItem {
+	anchorIdx := self.GetPanelState().GetSelectedLineIdx()
+
+	if self.GetItemAtIndex == nil || self.selection == nil || (!self.selection.active && len(self.selection.toggled) == 0) {
+		item, ok := self.GetSelectedItem()
+		if !ok {
+			return nil
+		}
+		return []ListItem{item}
+	}
+
+	indices := map[int]bool{anchorIdx: true}
+	if self.selection.active {
+		lo, hi := self.selection.anchorIdx, self.selection.rangeEndIdx
+		if lo > hi {
+			lo, hi = hi, lo
+		}
+		for i := lo; i <= hi; i++ {
+			indices[i] = true
+		}
+	}
+	for idx := range self.selection.toggled {
+		indices[idx] = true
+	}
+
+	sortedIndices := make([]int, 0, len(indices))
+	for idx := range indices {
+		sortedIndices = append(sortedIndices, idx)
+	}
+	sort.Ints(sortedIndices)
+
+	items := make([]ListItem, 0, len(sortedIndices))
+	for _, idx := range sortedIndices {
+		if item, ok := self.GetItemAtIndex(idx); ok {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // OnFocus assumes that the content of the context has already been rendered to the view. OnRender is the function which actually renders the content to the view
 func (self *ListContext) OnRender() error {
 	view, err := self.Gui.g.View(self.ViewName)
@@ -109,7 +315,11 @@ func (self *ListContext) OnRender() error {
 
 	if self.GetDisplayStrings != nil {
 		self.Gui.refreshSelectedLine(self.GetPanelState(), self.GetItemsLength())
-		self.Gui.renderDisplayStrings(view, self.GetDisplayStrings(0, self.GetItemsLength()))
+		// invalidate the cached window: the underlying items may have
+		// changed shape entirely (e.g. a fresh `git log`), so we can't trust
+		// that the previously rendered bounds are still valid.
+		self.lastWindow = nil
+		self.renderVisibleWindow(view)
 		self.Gui.render()
 	}
 
@@ -201,9 +411,33 @@ func (self *ListContext) handleLineChange(change int) error {
 
 	self.Gui.changeSelectedLine(self.GetPanelState(), self.GetItemsLength(), change)
 
+	if self.collapseRangeOnNavigation() {
+		self.notifySelectionChanged()
+	}
+
 	return self.HandleFocus()
 }
 
+// collapseRangeOnNavigation drops any active range-selection in response to
+// plain cursor movement (arrow keys, paging, goto-top/bottom): the range's
+// other end no longer means anything once the cursor has moved away on its
+// own. Toggled rows are left untouched, since they're independent of the
+// cursor - clearing them here would make keyboard-driven multi-select (e.g.
+// toggling several non-adjacent stashes with <space> before dropping them)
+// impossible, as every toggle beyond the first would require navigating to
+// it first. It returns whether the selection actually changed.
+func (self *ListContext) collapseRangeOnNavigation() bool {
+	if self.selection == nil || !self.selection.active {
+		return false
+	}
+
+	self.selection.active = false
+	if len(self.selection.toggled) == 0 {
+		self.selection = nil
+	}
+	return true
+}
+
 func (self *ListContext) handleNextPage() error {
 	view, err := self.Gui.g.View(self.ViewName)
 	if err != nil {
@@ -234,6 +468,23 @@ func (self *ListContext) handlePrevPage() error {
 }
 
 func (self *ListContext) handleClick() error {
+	return self.click(false, false)
+}
+
+// handleShiftClick extends the current range-selection to the clicked row,
+// for panels wired up to call this from a shift-click mouse binding.
+func (self *ListContext) handleShiftClick() error {
+	return self.click(true, false)
+}
+
+// handleCtrlClick toggles the clicked row in/out of the selection without
+// disturbing the rest of it, for panels wired up to call this from a
+// ctrl-click mouse binding.
+func (self *ListContext) handleCtrlClick() error {
+	return self.click(false, true)
+}
+
+func (self *ListContext) click(shift bool, ctrl bool) error {
 	if self.ignoreKeybinding() {
 		return nil
 	}
@@ -255,6 +506,32 @@ func (self *ListContext) handleClick() error {
 		return nil
 	}
 
+	switch {
+	case ctrl:
+		self.ensureSelection()
+		if self.selection.toggled[newSelectedLineIdx] {
+			delete(self.selection.toggled, newSelectedLineIdx)
+		} else {
+			self.selection.toggled[newSelectedLineIdx] = true
+		}
+		self.GetPanelState().SetSelectedLineIdx(newSelectedLineIdx)
+		self.notifySelectionChanged()
+		return self.HandleFocus()
+	case shift:
+		self.ensureSelection()
+		if !self.selection.active {
+			self.selection.active = true
+			self.selection.anchorIdx = prevSelectedLineIdx
+		}
+		self.selection.rangeEndIdx = newSelectedLineIdx
+		self.GetPanelState().SetSelectedLineIdx(newSelectedLineIdx)
+		self.notifySelectionChanged()
+		return self.HandleFocus()
+	}
+
+	if self.clearSelection() {
+		self.notifySelectionChanged()
+	}
 	self.GetPanelState().SetSelectedLineIdx(newSelectedLineIdx)
 
 	prevViewName := self.Gui.currentViewName()
@@ -264,7 +541,108 @@ func (self *ListContext) handleClick() error {
 	return self.HandleFocus()
 }
 
+func (self *ListContext) ensureSelection() {
+	if self.selection == nil {
+		self.selection = &selectionSet{toggled: map[int]bool{}}
+	}
+}
+
+// clearSelection drops any multi-selection and reports whether there was one.
+func (self *ListContext) clearSelection() bool {
+	hadSelection := self.selection != nil
+	self.selection = nil
+	return hadSelection
+}
+
+func (self *ListContext) notifySelectionChanged() {
+	self.selectionVersion++
+	if self.OnSelectionChanged != nil {
+		self.OnSelectionChanged()
+	}
+}
+
+// handleToggleSelect toggles the cursor row in/out of the selection
+// without disturbing the rest of it (e.g. bound to <space>).
+func (self *ListContext) handleToggleSelect() error {
+	if self.ignoreKeybinding() {
+		return nil
+	}
+
+	idx := self.GetPanelState().GetSelectedLineIdx()
+	self.ensureSelection()
+	if self.selection.toggled[idx] {
+		delete(self.selection.toggled, idx)
+	} else {
+		self.selection.toggled[idx] = true
+	}
+	self.notifySelectionChanged()
+	return self.HandleFocus()
+}
+
+func (self *ListContext) handleExtendSelectionUp() error {
+	return self.extendSelection(-1)
+}
+
+func (self *ListContext) handleExtendSelectionDown() error {
+	return self.extendSelection(1)
+}
+
+// extendSelection grows (or starts) a range-selection anchored at the
+// cursor's position before this extension began, and moves the cursor
+// along with it.
+func (self *ListContext) extendSelection(change int) error {
+	if self.ignoreKeybinding() {
+		return nil
+	}
+
+	currentIdx := self.GetPanelState().GetSelectedLineIdx()
+	newIdx := currentIdx + change
+	if newIdx < 0 || newIdx > self.GetItemsLength()-1 {
+		return nil
+	}
+
+	self.ensureSelection()
+	if !self.selection.active {
+		self.selection.active = true
+		self.selection.anchorIdx = currentIdx
+	}
+	self.selection.rangeEndIdx = newIdx
+
+	self.GetPanelState().SetSelectedLineIdx(newIdx)
+	self.notifySelectionChanged()
+	return self.HandleFocus()
+}
+
+// handleSelectAll selects every item in the panel.
+func (self *ListContext) handleSelectAll() error {
+	if self.ignoreKeybinding() {
+		return nil
+	}
+
+	self.ensureSelection()
+	self.selection.active = true
+	self.selection.anchorIdx = 0
+	self.selection.rangeEndIdx = self.GetItemsLength() - 1
+	self.notifySelectionChanged()
+	return self.HandleFocus()
+}
+
+// handleClearSelection drops any multi-selection, leaving just the cursor
+// item selected.
+func (self *ListContext) handleClearSelection() error {
+	if self.ignoreKeybinding() {
+		return nil
+	}
+
+	self.clearSelection()
+	self.notifySelectionChanged()
+	return self.HandleFocus()
+}
+
 func (self *ListContext) onSearchSelect(selectedLineIdx int) error {
+	if self.clearSelection() {
+		self.notifySelectionChanged()
+	}
 	self.GetPanelState().SetSelectedLineIdx(selectedLineIdx)
 	return self.HandleFocus()
 }
@@ -0,0 +1,165 @@
+package gui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectionSetContainsRange(t *testing.T) {
+	s := &selectionSet{active: true, anchorIdx: 4, rangeEndIdx: 2, toggled: map[int]bool{}}
+
+	assert.False(t, s.contains(1))
+	assert.True(t, s.contains(2))
+	assert.True(t, s.contains(3))
+	assert.True(t, s.contains(4))
+	assert.False(t, s.contains(5))
+}
+
+func TestSelectionSetContainsToggled(t *testing.T) {
+	s := &selectionSet{toggled: map[int]bool{2: true, 5: true}}
+
+	assert.True(t, s.contains(2))
+	assert.True(t, s.contains(5))
+	assert.False(t, s.contains(3))
+}
+
+func TestSelectionSetContainsNil(t *testing.T) {
+	var s *selectionSet
+	assert.False(t, s.contains(0))
+}
+
+// fakeListItem is the minimal ListItem used across these tests.
+type fakeListItem struct{ id string }
+
+func (i fakeListItem) ID() string          { return i.id }
+func (i fakeListItem) Description() string { return i.id }
+
+type fakePanelState struct{ selectedLineIdx int }
+
+func (s *fakePanelState) SetSelectedLineIdx(idx int) { s.selectedLineIdx = idx }
+func (s *fakePanelState) GetSelectedLineIdx() int    { return s.selectedLineIdx }
+
+func TestGetSelectedItemsNoSelection(t *testing.T) {
+	panelState := &fakePanelState{selectedLineIdx: 2}
+	items := []ListItem{fakeListItem{"a"}, fakeListItem{"b"}, fakeListItem{"c"}}
+
+	self := &ListContext{
+		OnGetPanelState: func() IListPanelState { return panelState },
+		SelectedItem:    func() (ListItem, bool) { return items[panelState.selectedLineIdx], true },
+	}
+
+	assert.Equal(t, []ListItem{fakeListItem{"c"}}, self.GetSelectedItems())
+}
+
+func TestGetSelectedItemsRangeAndToggledUnion(t *testing.T) {
+	panelState := &fakePanelState{selectedLineIdx: 4}
+	items := []ListItem{
+		fakeListItem{"a"}, fakeListItem{"b"}, fakeListItem{"c"},
+		fakeListItem{"d"}, fakeListItem{"e"}, fakeListItem{"f"},
+	}
+
+	self := &ListContext{
+		OnGetPanelState: func() IListPanelState { return panelState },
+		SelectedItem:    func() (ListItem, bool) { return items[panelState.selectedLineIdx], true },
+		GetItemAtIndex: func(idx int) (ListItem, bool) {
+			if idx < 0 || idx >= len(items) {
+				return nil, false
+			}
+			return items[idx], true
+		},
+	}
+	self.ensureSelection()
+	self.selection.active = true
+	self.selection.anchorIdx = 1
+	self.selection.rangeEndIdx = 2
+	self.selection.toggled[5] = true
+
+	// the union of the range {1,2}, the toggled row 5, and the cursor's own
+	// row 4, in index order and without duplicates.
+	expected := []ListItem{fakeListItem{"b"}, fakeListItem{"c"}, fakeListItem{"e"}, fakeListItem{"f"}}
+	assert.Equal(t, expected, self.GetSelectedItems())
+}
+
+func TestComputeRenderWindowMidScroll(t *testing.T) {
+	// scrolled well away from both the top and bottom of a long list: the window
+	// should be centred on the viewport with overscan on both sides.
+	startIdx, length := computeRenderWindow(1000, 100, 20)
+
+	assert.Equal(t, 90, startIdx)
+	assert.Equal(t, 41, length) // innerHeight + overscan on both sides + the origin row itself
+}
+
+func TestComputeRenderWindowClampsAtStart(t *testing.T) {
+	startIdx, length := computeRenderWindow(1000, 3, 20)
+
+	assert.Equal(t, 0, startIdx)
+	assert.Equal(t, 34, length) // overscan above is clamped away rather than shifted onto the end
+}
+
+func TestComputeRenderWindowClampsAtEnd(t *testing.T) {
+	startIdx, length := computeRenderWindow(50, 45, 20)
+
+	assert.Equal(t, 35, startIdx)
+	assert.Equal(t, 15, length) // can't read past the last item
+}
+
+func TestComputeRenderWindowShorterThanOverscan(t *testing.T) {
+	startIdx, length := computeRenderWindow(3, 0, 20)
+
+	assert.Equal(t, 0, startIdx)
+	assert.Equal(t, 3, length)
+}
+
+func TestRenderedWindowCacheKeyDiffersOnSelectionVersion(t *testing.T) {
+	a := renderedWindow{startIdx: 0, length: 20, contentVersion: 1, selectionVersion: 1}
+	b := renderedWindow{startIdx: 0, length: 20, contentVersion: 1, selectionVersion: 2}
+
+	// toggling a selection without scrolling must not look like a no-op
+	// cache hit, or the new highlight never gets rendered (the bug fixed
+	// alongside this test).
+	assert.NotEqual(t, a, b)
+}
+
+func TestCollapseRangeOnNavigationKeepsToggledSelection(t *testing.T) {
+	self := &ListContext{}
+	self.ensureSelection()
+	self.selection.toggled[2] = true
+
+	changed := self.collapseRangeOnNavigation()
+
+	assert.False(t, changed)
+	if assert.NotNil(t, self.selection) {
+		assert.True(t, self.selection.toggled[2])
+	}
+}
+
+func TestCollapseRangeOnNavigationClearsPureRangeSelection(t *testing.T) {
+	self := &ListContext{}
+	self.ensureSelection()
+	self.selection.active = true
+	self.selection.anchorIdx = 1
+	self.selection.rangeEndIdx = 3
+
+	changed := self.collapseRangeOnNavigation()
+
+	assert.True(t, changed)
+	assert.Nil(t, self.selection)
+}
+
+func TestCollapseRangeOnNavigationDropsRangeButKeepsToggled(t *testing.T) {
+	self := &ListContext{}
+	self.ensureSelection()
+	self.selection.active = true
+	self.selection.anchorIdx = 1
+	self.selection.rangeEndIdx = 3
+	self.selection.toggled[5] = true
+
+	changed := self.collapseRangeOnNavigation()
+
+	assert.True(t, changed)
+	if assert.NotNil(t, self.selection) {
+		assert.False(t, self.selection.active)
+		assert.True(t, self.selection.toggled[5])
+	}
+}
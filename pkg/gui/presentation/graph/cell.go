@@ -11,6 +11,11 @@ const (
 	CONNECTION cellType = iota
 	COMMIT
 	MERGE
+	// DIAGONAL_LEFT and DIAGONAL_RIGHT are used by layouts (e.g. the
+	// straight layout) that route a merge into a lane with a diagonal
+	// connector rather than a horizontal one.
+	DIAGONAL_LEFT
+	DIAGONAL_RIGHT
 )
 
 type Cell struct {
@@ -32,6 +37,10 @@ func (cell *Cell) render() string {
 		adjustedFirst = commitSymbol
 	case MERGE:
 		adjustedFirst = mergeSymbol
+	case DIAGONAL_LEFT:
+		adjustedFirst = '╲'
+	case DIAGONAL_RIGHT:
+		adjustedFirst = '╱'
 	}
 
 	var rightStyle *style.TextStyle
@@ -44,6 +53,13 @@ func (cell *Cell) render() string {
 	return cell.style.Sprint(string(adjustedFirst)) + rightStyle.Sprint(string(second))
 }
 
+// isBlank reports whether the cell has nothing to draw: no connecting
+// lines and no commit/merge glyph. Layouts use this to find lanes that can
+// be collapsed out of the rendered graph.
+func (cell *Cell) isBlank() bool {
+	return cell.cellType == CONNECTION && !cell.up && !cell.down && !cell.left && !cell.right
+}
+
 func (cell *Cell) reset() {
 	cell.up = false
 	cell.down = false
@@ -0,0 +1,16 @@
+package graph
+
+// RenderCommitGraph lays out commits using the named engine (see
+// NewLayoutEngine) and renders each row to a display string. This is the
+// single entry point the commits view should call instead of constructing
+// Cells by hand, so that switching the `gui.commitGraph` user config value
+// actually changes what gets drawn.
+func RenderCommitGraph(commits []*CommitNode, engineName string) []string {
+	rows := NewLayoutEngine(engineName).Layout(commits)
+
+	out := make([]string, len(rows))
+	for i, row := range rows {
+		out[i] = renderCells(row)
+	}
+	return out
+}
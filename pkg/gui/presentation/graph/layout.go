@@ -0,0 +1,49 @@
+package graph
+
+// CommitNode is the minimal information a LayoutEngine needs about a commit
+// in order to lay out the graph: its own SHA and the SHAs of its parents,
+// in parent order (first parent first). Commits are expected newest-first,
+// the same order `git log` produces them in.
+type CommitNode struct {
+	Sha     string
+	Parents []string
+}
+
+// LayoutEngine turns an ordered slice of commits into one row of Cells per
+// commit, describing how to draw the graph column(s) for that commit.
+type LayoutEngine interface {
+	Layout(commits []*CommitNode) [][]*Cell
+}
+
+// NewLayoutEngine resolves a user-facing config value (the `gui.commitGraph`
+// user config option) to a LayoutEngine implementation. Unknown values fall
+// back to the classic layout so that existing configs keep working. Call it
+// through RenderCommitGraph rather than directly, unless you're writing a
+// test against a specific engine.
+func NewLayoutEngine(name string) LayoutEngine {
+	switch name {
+	case "straight":
+		return &straightLayoutEngine{}
+	case "compact":
+		return &compactLayoutEngine{inner: &classicLayoutEngine{}, maxGap: defaultCompactGap}
+	default:
+		return &classicLayoutEngine{}
+	}
+}
+
+// lane tracks, for a single column in the graph, the SHA of the commit whose
+// line is currently running through it. An empty sha means the lane is free.
+type lane struct {
+	sha string
+}
+
+// firstFreeLane returns the index of the first free lane, or len(lanes) if
+// they're all taken (meaning the caller should append a new one).
+func firstFreeLane(lanes []lane) int {
+	for idx, l := range lanes {
+		if l.sha == "" {
+			return idx
+		}
+	}
+	return len(lanes)
+}
@@ -0,0 +1,109 @@
+package graph
+
+import "github.com/jesseduffield/lazygit/pkg/gui/style"
+
+// classicLayoutEngine is the original layout: each commit is placed in the
+// first free lane, and a lane is freed up as soon as the branch running
+// through it ends (or converges back into a lane already occupied by the
+// same ancestor, as happens with criss-crossing merges).
+type classicLayoutEngine struct{}
+
+func (e *classicLayoutEngine) Layout(commits []*CommitNode) [][]*Cell {
+	lanes := []lane{}
+	rows := make([][]*Cell, len(commits))
+
+	for i, commit := range commits {
+		commitLaneIdx := -1
+		for idx := range lanes {
+			if lanes[idx].sha == commit.Sha {
+				commitLaneIdx = idx
+				break
+			}
+		}
+		if commitLaneIdx == -1 {
+			commitLaneIdx = firstFreeLane(lanes)
+			if commitLaneIdx == len(lanes) {
+				lanes = append(lanes, lane{})
+			}
+		}
+		lanes[commitLaneIdx] = lane{sha: commit.Sha}
+
+		row := make([]*Cell, len(lanes))
+		for idx := range row {
+			row[idx] = &Cell{}
+		}
+		touched := map[int]bool{commitLaneIdx: true}
+
+		// any other lane that was waiting on this exact same sha converges
+		// into the commit's lane here, e.g. two branches that both merge
+		// back into the same ancestor.
+		for idx := range lanes {
+			if idx == commitLaneIdx || lanes[idx].sha != commit.Sha {
+				continue
+			}
+			row[idx].setUp(style.TextStyle{})
+			connectLanes(row, commitLaneIdx, idx)
+			touched[idx] = true
+			lanes[idx] = lane{}
+		}
+
+		cellType := COMMIT
+		if len(commit.Parents) > 1 {
+			cellType = MERGE
+		}
+		row[commitLaneIdx].setType(cellType)
+		if i > 0 {
+			row[commitLaneIdx].setUp(style.TextStyle{})
+		}
+
+		if len(commit.Parents) == 0 {
+			lanes[commitLaneIdx] = lane{}
+		} else {
+			row[commitLaneIdx].setDown(style.TextStyle{})
+			// the first parent continues in the commit's own lane
+			lanes[commitLaneIdx] = lane{sha: commit.Parents[0]}
+
+			// every additional parent opens (or joins) another lane, routed
+			// to with a horizontal connector
+			for _, parentSha := range commit.Parents[1:] {
+				parentLaneIdx := firstFreeLane(lanes)
+				if parentLaneIdx == len(lanes) {
+					lanes = append(lanes, lane{})
+					row = append(row, &Cell{})
+				}
+				lanes[parentLaneIdx] = lane{sha: parentSha}
+				touched[parentLaneIdx] = true
+				row[parentLaneIdx].setType(CONNECTION).setDown(style.TextStyle{})
+				connectLanes(row, commitLaneIdx, parentLaneIdx)
+				touched[commitLaneIdx] = true
+			}
+		}
+
+		// any other lane that's still occupied just passes straight through
+		// this row: it was active before and remains active after.
+		for idx := range lanes {
+			if touched[idx] || lanes[idx].sha == "" {
+				continue
+			}
+			row[idx].setUp(style.TextStyle{}).setDown(style.TextStyle{})
+		}
+
+		rows[i] = row
+	}
+
+	return rows
+}
+
+// connectLanes draws a horizontal connector out of every cell from the lower
+// of lanes a and b up to, but not including, the higher one, so that a
+// commit and an extra parent/convergence lane on another column read as
+// joined.
+func connectLanes(row []*Cell, a, b int) {
+	lo, hi := a, b
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	for l := lo; l < hi; l++ {
+		row[l].setRight(style.TextStyle{}, false)
+	}
+}
@@ -0,0 +1,83 @@
+package graph
+
+// defaultCompactGap is the number of consecutive blank rows a lane is
+// allowed before the compact layout collapses its column out of the graph.
+const defaultCompactGap = 3
+
+// compactLayoutEngine wraps another engine's output and drops a column from
+// just the rows where it goes unused (no connecting lines, no commit/merge
+// glyph) for more than maxGap consecutive rows. This keeps wide, sparse
+// histories (lots of short-lived branches) from pushing the actual commit
+// message off-screen, without touching rows where that same column index
+// is later reused by an unrelated branch and holds real content.
+type compactLayoutEngine struct {
+	inner  LayoutEngine
+	maxGap int
+}
+
+func (e *compactLayoutEngine) Layout(commits []*CommitNode) [][]*Cell {
+	rows := e.inner.Layout(commits)
+	return collapseEmptyColumns(rows, e.maxGap)
+}
+
+func collapseEmptyColumns(rows [][]*Cell, maxGap int) [][]*Cell {
+	if len(rows) == 0 {
+		return rows
+	}
+
+	width := 0
+	for _, row := range rows {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+
+	// drop[r][col] marks that column's cell in row r as part of a blank
+	// run longer than maxGap, and therefore safe to splice out. A column
+	// can be dropped for one stretch of rows and kept for another, since
+	// classicLayoutEngine frees and reuses lane indices across unrelated
+	// branches.
+	drop := make([][]bool, len(rows))
+	for i := range rows {
+		drop[i] = make([]bool, width)
+	}
+
+	for col := 0; col < width; col++ {
+		runStart := -1
+		flushRun := func(end int) {
+			if runStart == -1 {
+				return
+			}
+			if end-runStart > maxGap {
+				for r := runStart; r < end; r++ {
+					drop[r][col] = true
+				}
+			}
+			runStart = -1
+		}
+
+		for r, row := range rows {
+			blank := col >= len(row) || row[col].isBlank()
+			if blank {
+				if runStart == -1 {
+					runStart = r
+				}
+				continue
+			}
+			flushRun(r)
+		}
+		flushRun(len(rows))
+	}
+
+	out := make([][]*Cell, len(rows))
+	for i, row := range rows {
+		newRow := make([]*Cell, 0, len(row))
+		for col, cell := range row {
+			if !drop[i][col] {
+				newRow = append(newRow, cell)
+			}
+		}
+		out[i] = newRow
+	}
+	return out
+}
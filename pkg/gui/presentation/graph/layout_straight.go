@@ -0,0 +1,157 @@
+package graph
+
+import "github.com/jesseduffield/lazygit/pkg/gui/style"
+
+// straightLayoutEngine keeps every branch on a fixed column for its entire
+// lifetime in the rendered history: a branch's lane is derived from a
+// stable hash of the SHA at its tip, rather than being reused from whichever
+// lane happens to be free next. Merges into another lane are drawn with
+// diagonal connectors instead of re-routing horizontally through shared
+// columns.
+type straightLayoutEngine struct{}
+
+func (e *straightLayoutEngine) Layout(commits []*CommitNode) [][]*Cell {
+	lanes := []string{} // sha currently occupying each lane, "" if free
+	laneOf := map[string]int{}
+
+	laneForSha := func(sha string) int {
+		if idx, ok := laneOf[sha]; ok {
+			return idx
+		}
+		idx := stableLane(sha, &lanes)
+		laneOf[sha] = idx
+		return idx
+	}
+
+	rows := make([][]*Cell, len(commits))
+	for i, commit := range commits {
+		commitLane := laneForSha(commit.Sha)
+		row := growRow(nil, commitLane)
+		// touched tracks every lane this commit actually draws into, so the
+		// pass-through pass below knows which lanes it still needs to carry
+		// a plain vertical line through (mirroring classicLayoutEngine).
+		touched := map[int]bool{commitLane: true}
+
+		cellType := COMMIT
+		if len(commit.Parents) > 1 {
+			cellType = MERGE
+		}
+		row[commitLane].setType(cellType)
+		if i > 0 {
+			row[commitLane].setUp(style.TextStyle{})
+		}
+
+		if len(commit.Parents) == 0 {
+			// the branch ends here: free the lane so an unrelated branch
+			// discovered later can reuse this column instead of the lane
+			// count growing forever.
+			lanes[commitLane] = ""
+		} else {
+			row[commitLane].setDown(style.TextStyle{})
+
+			firstParent := commit.Parents[0]
+			if existingLane, ok := laneOf[firstParent]; ok && existingLane != commitLane {
+				// the first parent already lives in another lane (reached
+				// earlier via a different branch), so this branch's lane
+				// ends here too - it's converging into that one instead.
+				// Draw a diagonal connector into that lane so the merge
+				// reads as joining it rather than just stopping dead.
+				lanes[commitLane] = ""
+				row = growRow(row, existingLane)
+				diagType := DIAGONAL_RIGHT
+				if existingLane < commitLane {
+					diagType = DIAGONAL_LEFT
+				}
+				row[existingLane].setType(diagType).setDown(style.TextStyle{})
+				touched[existingLane] = true
+			} else {
+				// the first parent continues this exact branch, so it
+				// inherits the same lane rather than being assigned a
+				// fresh one by laneForSha. This is what keeps a branch on
+				// a single fixed column for its whole lifetime.
+				laneOf[firstParent] = commitLane
+				lanes[commitLane] = firstParent
+			}
+
+			for parentIdx, parentSha := range commit.Parents {
+				if parentIdx == 0 {
+					continue
+				}
+				parentLane := laneForSha(parentSha)
+				row = growRow(row, parentLane)
+
+				diagType := DIAGONAL_RIGHT
+				if parentLane < commitLane {
+					diagType = DIAGONAL_LEFT
+				}
+				row[parentLane].setType(diagType).setDown(style.TextStyle{})
+				lanes[parentLane] = parentSha
+				touched[parentLane] = true
+			}
+		}
+
+		// any other lane that's still occupied just passes straight through
+		// this row, same as classicLayoutEngine: it was active before and
+		// remains active after, so it still needs a continuation line even
+		// though this commit doesn't touch it.
+		row = growRow(row, len(lanes)-1)
+		for idx, sha := range lanes {
+			if touched[idx] || sha == "" {
+				continue
+			}
+			row[idx].setUp(style.TextStyle{}).setDown(style.TextStyle{})
+		}
+
+		rows[i] = row
+	}
+
+	return rows
+}
+
+// growRow ensures row has at least idx+1 cells, allocating fresh blank
+// cells for any newly added slots.
+func growRow(row []*Cell, idx int) []*Cell {
+	for len(row) <= idx {
+		row = append(row, &Cell{})
+	}
+	return row
+}
+
+// stableLane assigns sha a lane deterministically: it hashes the SHA to a
+// starting column and then probes forward for the first free lane, growing
+// the lane slice if every existing lane is taken. This keeps a branch glued
+// to roughly the same column across the whole render instead of collapsing
+// onto whatever lane a sibling branch just vacated.
+func stableLane(sha string, lanes *[]string) int {
+	if len(*lanes) == 0 {
+		*lanes = append(*lanes, sha)
+		return 0
+	}
+
+	start := int(fnv32(sha) % uint32(len(*lanes)))
+	for offset := 0; offset < len(*lanes); offset++ {
+		idx := (start + offset) % len(*lanes)
+		if (*lanes)[idx] == "" {
+			(*lanes)[idx] = sha
+			return idx
+		}
+	}
+
+	*lanes = append(*lanes, sha)
+	return len(*lanes) - 1
+}
+
+// fnv32 is a small, dependency-free FNV-1a hash, good enough to spread SHAs
+// across lanes without needing them to be cryptographically distributed.
+func fnv32(s string) uint32 {
+	const (
+		offset32 = 2166136261
+		prime32  = 16777619
+	)
+	hash := uint32(offset32)
+	for i := 0; i < len(s); i++ {
+		hash ^= uint32(s[i])
+		hash *= prime32
+	}
+	return hash
+}
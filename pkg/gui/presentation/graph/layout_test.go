@@ -0,0 +1,213 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func render(rows [][]*Cell) []string {
+	result := make([]string, len(rows))
+	for i, row := range rows {
+		result[i] = renderCells(row)
+	}
+	return result
+}
+
+func TestClassicLayoutEngineLinear(t *testing.T) {
+	commits := []*CommitNode{
+		{Sha: "a", Parents: []string{"b"}},
+		{Sha: "b", Parents: []string{"c"}},
+		{Sha: "c", Parents: []string{}},
+	}
+
+	rows := (&classicLayoutEngine{}).Layout(commits)
+
+	expected := []string{
+		"⎔ ",
+		"⎔ ",
+		"⎔ ",
+	}
+	assert.Equal(t, expected, render(rows))
+}
+
+func TestClassicLayoutEngineOctopusMerge(t *testing.T) {
+	commits := []*CommitNode{
+		{Sha: "m", Parents: []string{"p1", "p2", "p3"}},
+		{Sha: "p1", Parents: []string{}},
+		{Sha: "p2", Parents: []string{}},
+		{Sha: "p3", Parents: []string{}},
+	}
+
+	rows := (&classicLayoutEngine{}).Layout(commits)
+
+	expected := []string{
+		"⏣─┌─╷ ",
+		"⎔ │ │ ",
+		"  ⎔ │ ",
+		"    ⎔ ",
+	}
+	assert.Equal(t, expected, render(rows))
+}
+
+func TestClassicLayoutEngineCrissCross(t *testing.T) {
+	commits := []*CommitNode{
+		{Sha: "m", Parents: []string{"a", "b"}},
+		{Sha: "a", Parents: []string{"c"}},
+		{Sha: "b", Parents: []string{"c"}},
+		{Sha: "c", Parents: []string{}},
+	}
+
+	rows := (&classicLayoutEngine{}).Layout(commits)
+
+	expected := []string{
+		"⏣─╷ ",
+		"⎔ │ ",
+		"│ ⎔ ",
+		"⎔─╵ ",
+	}
+	assert.Equal(t, expected, render(rows))
+}
+
+func TestStraightLayoutEngineKeepsBranchOnItsLane(t *testing.T) {
+	commits := []*CommitNode{
+		{Sha: "a", Parents: []string{"b"}},
+		{Sha: "b", Parents: []string{"c"}},
+		{Sha: "c", Parents: []string{}},
+	}
+
+	rows := (&straightLayoutEngine{}).Layout(commits)
+
+	assert.Equal(t, 3, len(rows))
+	// a single unbranching chain should never need a second lane: each
+	// commit's first parent inherits its exact lane instead of being
+	// assigned a fresh one, so the whole history stays 1 column wide.
+	for _, row := range rows {
+		assert.Equal(t, 1, len(row))
+	}
+	assert.Equal(t, COMMIT, rows[0][0].cellType)
+	assert.Equal(t, COMMIT, rows[1][0].cellType)
+	assert.Equal(t, COMMIT, rows[2][0].cellType)
+}
+
+func TestStraightLayoutEngineOctopusMerge(t *testing.T) {
+	commits := []*CommitNode{
+		{Sha: "m", Parents: []string{"p1", "p2", "p3"}},
+		{Sha: "p1", Parents: []string{}},
+		{Sha: "p2", Parents: []string{}},
+		{Sha: "p3", Parents: []string{}},
+	}
+
+	rows := (&straightLayoutEngine{}).Layout(commits)
+
+	// every lane that's still in flight (p2, p3 waiting to be rendered)
+	// must carry a continuation line through rows where this commit
+	// doesn't touch it, same as classicLayoutEngine - otherwise the
+	// vertical connectors break for a row and the graph desyncs from the
+	// text column next to it.
+	expected := []string{
+		"⏣ ╱ ╱ ",
+		"⎔ │ │ ",
+		"  ⎔ │ ",
+		"    ⎔ ",
+	}
+	assert.Equal(t, expected, render(rows))
+}
+
+func TestStraightLayoutEngineCrissCross(t *testing.T) {
+	commits := []*CommitNode{
+		{Sha: "m", Parents: []string{"a", "b"}},
+		{Sha: "a", Parents: []string{"c"}},
+		{Sha: "b", Parents: []string{"c"}},
+		{Sha: "c", Parents: []string{}},
+	}
+
+	rows := (&straightLayoutEngine{}).Layout(commits)
+
+	// "b"'s first parent ("c") already lives in another lane by the time
+	// "b" is laid out, so it converges with a diagonal connector instead
+	// of just vanishing with no indication it merged.
+	expected := []string{
+		"⏣ ╱ ",
+		"⎔ │ ",
+		"╲ ⎔ ",
+		"⎔   ",
+	}
+	assert.Equal(t, expected, render(rows))
+}
+
+func TestCompactLayoutEngineOctopusMergePreservesContent(t *testing.T) {
+	commits := []*CommitNode{
+		{Sha: "m", Parents: []string{"p1", "p2", "p3"}},
+		{Sha: "p1", Parents: []string{}},
+		{Sha: "p2", Parents: []string{}},
+		{Sha: "p3", Parents: []string{}},
+	}
+
+	classicRows := (&classicLayoutEngine{}).Layout(commits)
+	compactRows := collapseEmptyColumns(classicRows, 2)
+
+	// no lane's blank run here is longer than maxGap (lane 0's is exactly
+	// maxGap, which is still kept), so
+	// compact mode must leave the whole graph untouched.
+	assert.Equal(t, render(classicRows), render(compactRows))
+}
+
+func TestCompactLayoutEngineCrissCrossPreservesContent(t *testing.T) {
+	commits := []*CommitNode{
+		{Sha: "m", Parents: []string{"a", "b"}},
+		{Sha: "a", Parents: []string{"c"}},
+		{Sha: "b", Parents: []string{"c"}},
+		{Sha: "c", Parents: []string{}},
+	}
+
+	classicRows := (&classicLayoutEngine{}).Layout(commits)
+	compactRows := collapseEmptyColumns(classicRows, 2)
+
+	assert.Equal(t, render(classicRows), render(compactRows))
+}
+
+func TestRenderCommitGraphDispatchesOnEngineName(t *testing.T) {
+	commits := []*CommitNode{
+		{Sha: "m", Parents: []string{"p1", "p2", "p3"}},
+		{Sha: "p1", Parents: []string{}},
+		{Sha: "p2", Parents: []string{}},
+		{Sha: "p3", Parents: []string{}},
+	}
+
+	classic := RenderCommitGraph(commits, "classic")
+	straight := RenderCommitGraph(commits, "straight")
+	unknown := RenderCommitGraph(commits, "")
+
+	assert.Equal(t, classic, unknown, "an unrecognised engine name should fall back to classic")
+	assert.NotEqual(t, classic, straight, "different engine names must produce different output")
+}
+
+func TestCompactLayoutEngineDropsLongRunningGaps(t *testing.T) {
+	// "side" opens a second lane via the merge commit but is a one-commit
+	// branch, while "main" keeps going for several more rows. Lane 1 sits
+	// blank for longer than the gap threshold once "side" is resolved, so
+	// compact mode should drop it from those rows.
+	commits := []*CommitNode{
+		{Sha: "m", Parents: []string{"main1", "side"}},
+		{Sha: "side", Parents: []string{}},
+		{Sha: "main1", Parents: []string{"main2"}},
+		{Sha: "main2", Parents: []string{"main3"}},
+		{Sha: "main3", Parents: []string{"main4"}},
+		{Sha: "main4", Parents: []string{}},
+	}
+
+	classicRows := (&classicLayoutEngine{}).Layout(commits)
+	compactRows := collapseEmptyColumns(classicRows, 2)
+
+	// lane 1 is real content on rows 0-1 (the merge's extra parent and
+	// "side"'s own commit glyph) - those rows must be untouched even
+	// though the same column goes on to sit blank for the rest of the
+	// history.
+	assert.Equal(t, 2, len(compactRows[0]))
+	assert.Equal(t, 2, len(compactRows[1]))
+
+	lastRow := len(classicRows) - 1
+	assert.Equal(t, 2, len(classicRows[lastRow]))
+	assert.Equal(t, 1, len(compactRows[lastRow]))
+}